@@ -0,0 +1,69 @@
+// Package core defines the shared interfaces used by limits and strategies:
+// the limiter's outcome callback (Listener) and the metric reporting
+// abstraction (MetricRegistry).
+package core
+
+// Listener is returned to a caller that has successfully, or unsuccessfully,
+// attempted to acquire capacity from a Strategy. It lets the caller report
+// the outcome of the guarded operation back to the limit algorithm, and lets
+// the caller (or anyone holding a reference) give the token back.
+type Listener interface {
+	// OnSuccess is called to indicate the guarded operation succeeded.
+	OnSuccess()
+	// OnIgnore is called to indicate the operation's outcome should not be
+	// used to update the limit (e.g. it failed for a reason unrelated to
+	// load, such as a bad request).
+	OnIgnore()
+	// OnDropped is called to indicate the operation failed in a way that
+	// should count against the limit, e.g. it was rejected or timed out.
+	OnDropped()
+	// IsAcquired returns true if this listener represents a successfully
+	// acquired token.
+	IsAcquired() bool
+	// Release returns the token to the strategy that issued it, freeing up
+	// capacity for a subsequent caller. Release must be idempotent: calling
+	// it more than once must not free capacity twice.
+	Release()
+}
+
+// SampleListener receives individual samples for a registered metric.
+type SampleListener interface {
+	// AddSample records a single observation.
+	AddSample(value float64)
+}
+
+// MetricRegistry is the abstraction strategies and limits use to publish
+// metrics without depending on a concrete metrics backend.
+type MetricRegistry interface {
+	// RegisterGauge registers, or looks up, a gauge metric with the given
+	// name and tags.
+	RegisterGauge(name string, tags ...string) SampleListener
+	// RegisterDistribution registers, or looks up, a distribution/histogram
+	// metric with the given name and tags.
+	RegisterDistribution(name string, tags ...string) SampleListener
+	// RegisterCounter registers, or looks up, a counter metric with the
+	// given name and tags.
+	RegisterCounter(name string, tags ...string) SampleListener
+}
+
+type emptySampleListener struct{}
+
+func (emptySampleListener) AddSample(value float64) {}
+
+type emptyMetricRegistry struct{}
+
+func (emptyMetricRegistry) RegisterGauge(name string, tags ...string) SampleListener {
+	return emptySampleListener{}
+}
+
+func (emptyMetricRegistry) RegisterDistribution(name string, tags ...string) SampleListener {
+	return emptySampleListener{}
+}
+
+func (emptyMetricRegistry) RegisterCounter(name string, tags ...string) SampleListener {
+	return emptySampleListener{}
+}
+
+// EmptyMetricRegistryInstance is a no-op MetricRegistry, useful for tests and
+// callers that don't want to wire up metrics reporting.
+var EmptyMetricRegistryInstance MetricRegistry = emptyMetricRegistry{}
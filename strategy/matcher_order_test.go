@@ -0,0 +1,41 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systay/go-concurrency-limits/core"
+)
+
+// TestPartitionOrderFirstMatchWins verifies that a PredicatePartitionStrategy
+// routes to the first partition in the list whose predicate matches, even
+// when a later partition's predicate would also match.
+func TestPartitionOrderFirstMatchWins(t *testing.T) {
+	t.Parallel()
+	asrt := assert.New(t)
+
+	matchAnything := func(ctx context.Context) bool { return true }
+
+	first := NewPredicatePartitionWithMetricRegistry("first", 0.5, matchAnything, core.EmptyMetricRegistryInstance)
+	second := NewPredicatePartitionWithMetricRegistry("second", 0.5, matchAnything, core.EmptyMetricRegistryInstance)
+
+	s, err := NewPredicatePartitionStrategyWithMetricRegistry(
+		[]*PredicatePartition{first, second},
+		10,
+		core.EmptyMetricRegistryInstance)
+	asrt.NoError(err)
+
+	token, ok := s.TryAcquire(context.Background())
+	asrt.True(ok)
+	asrt.NotNil(token)
+
+	firstBusy, err := s.BinBusyCount(0)
+	asrt.NoError(err)
+	secondBusy, err := s.BinBusyCount(1)
+	asrt.NoError(err)
+
+	asrt.Equal(1, firstBusy)
+	asrt.Equal(0, secondBusy)
+}
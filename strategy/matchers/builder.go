@@ -0,0 +1,86 @@
+package matchers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// MatcherConfig is a declarative description of a matcher, suitable for
+// unmarshalling from a YAML or JSON partitioning policy file and compiling
+// with MatcherBuilder. Matchers is only used by the "allOf", "anyOf", and
+// "not" combinator types.
+type MatcherConfig struct {
+	Type            string          `json:"type"`
+	Key             string          `json:"key,omitempty"`
+	Value           string          `json:"value,omitempty"`
+	CaseInsensitive bool            `json:"caseInsensitive,omitempty"`
+	Min             int64           `json:"min,omitempty"`
+	Max             int64           `json:"max,omitempty"`
+	Header          string          `json:"header,omitempty"`
+	Values          []string        `json:"values,omitempty"`
+	Matchers        []MatcherConfig `json:"matchers,omitempty"`
+}
+
+// MatcherBuilder compiles MatcherConfig values into matcher functions, so
+// operators can change partitioning policy by editing a config file rather
+// than recompiling.
+type MatcherBuilder struct{}
+
+// NewMatcherBuilder creates a MatcherBuilder.
+func NewMatcherBuilder() *MatcherBuilder {
+	return &MatcherBuilder{}
+}
+
+// Build compiles a single MatcherConfig into a matcher function.
+func (b *MatcherBuilder) Build(cfg MatcherConfig) (func(ctx context.Context) bool, error) {
+	switch cfg.Type {
+	case "string":
+		return StringPredicateMatcher(cfg.Value, cfg.CaseInsensitive), nil
+
+	case "regexp":
+		re, err := regexp.Compile(cfg.Value)
+		if err != nil {
+			return nil, fmt.Errorf("matchers: invalid regexp %q: %w", cfg.Value, err)
+		}
+		return RegexpMatcher(ContextKey(cfg.Key), re), nil
+
+	case "numericRange":
+		return NumericRangeMatcher(ContextKey(cfg.Key), cfg.Min, cfg.Max), nil
+
+	case "header":
+		return HeaderMatcher(ContextKey(cfg.Key), cfg.Header, cfg.Values...), nil
+
+	case "allOf", "anyOf", "not":
+		children, err := b.buildChildren(cfg.Matchers)
+		if err != nil {
+			return nil, err
+		}
+		switch cfg.Type {
+		case "allOf":
+			return AllOf(children...), nil
+		case "anyOf":
+			return AnyOf(children...), nil
+		default: // "not"
+			if len(children) != 1 {
+				return nil, fmt.Errorf("matchers: %q requires exactly one child matcher, got %d", cfg.Type, len(children))
+			}
+			return Not(children[0]), nil
+		}
+
+	default:
+		return nil, fmt.Errorf("matchers: unknown matcher type %q", cfg.Type)
+	}
+}
+
+func (b *MatcherBuilder) buildChildren(configs []MatcherConfig) ([]func(ctx context.Context) bool, error) {
+	children := make([]func(ctx context.Context) bool, 0, len(configs))
+	for _, c := range configs {
+		m, err := b.Build(c)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, m)
+	}
+	return children, nil
+}
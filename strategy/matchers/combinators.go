@@ -0,0 +1,38 @@
+package matchers
+
+import "context"
+
+// AllOf combines matchers into one that reports true only if every matcher
+// does. Matchers are evaluated in order and evaluation stops at the first
+// one that returns false.
+func AllOf(matchers ...func(ctx context.Context) bool) func(ctx context.Context) bool {
+	return func(ctx context.Context) bool {
+		for _, m := range matchers {
+			if !m(ctx) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf combines matchers into one that reports true if any matcher does.
+// Matchers are evaluated in order and evaluation stops at the first one
+// that returns true.
+func AnyOf(matchers ...func(ctx context.Context) bool) func(ctx context.Context) bool {
+	return func(ctx context.Context) bool {
+		for _, m := range matchers {
+			if m(ctx) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not negates a matcher.
+func Not(matcher func(ctx context.Context) bool) func(ctx context.Context) bool {
+	return func(ctx context.Context) bool {
+		return !matcher(ctx)
+	}
+}
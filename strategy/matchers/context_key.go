@@ -0,0 +1,10 @@
+package matchers
+
+// ContextKey is a comparable context.Context key used by the matchers in
+// this package that extract a caller-named value, rather than sharing the
+// single StringPredicateContextKey.
+type ContextKey string
+
+func (c ContextKey) String() string {
+	return "go-concurrency-limits|strategy|matcher|" + string(c)
+}
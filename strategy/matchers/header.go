@@ -0,0 +1,41 @@
+package matchers
+
+import (
+	"context"
+	"net/http"
+)
+
+// HeaderMatcher implements a matcher that extracts an http.Header from ctx
+// under key and reports whether headerName is present on it. If one or more
+// expected values are given, at least one of the header's (possibly
+// multi-valued) entries must equal one of them; otherwise mere presence of
+// the header is sufficient.
+func HeaderMatcher(key ContextKey, headerName string, expected ...string) func(ctx context.Context) bool {
+	return func(ctx context.Context) bool {
+		val := ctx.Value(key)
+		if val == nil {
+			return false
+		}
+		header, ok := val.(http.Header)
+		if !ok {
+			return false
+		}
+
+		values := header.Values(headerName)
+		if len(values) == 0 {
+			return false
+		}
+		if len(expected) == 0 {
+			return true
+		}
+
+		for _, v := range values {
+			for _, e := range expected {
+				if v == e {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
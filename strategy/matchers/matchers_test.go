@@ -0,0 +1,121 @@
+package matchers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexpMatcher(t *testing.T) {
+	t.Parallel()
+	asrt := assert.New(t)
+	key := ContextKey("path")
+	matcher := RegexpMatcher(key, regexp.MustCompile(`^/api/v\d+/`))
+
+	ctx := context.WithValue(context.Background(), key, "/api/v2/widgets")
+	asrt.True(matcher(ctx))
+
+	ctx = context.WithValue(context.Background(), key, "/health")
+	asrt.False(matcher(ctx))
+
+	asrt.False(matcher(context.Background()))
+}
+
+func TestNumericRangeMatcher(t *testing.T) {
+	t.Parallel()
+	asrt := assert.New(t)
+	key := ContextKey("tenantTier")
+	matcher := NumericRangeMatcher(key, 1, 3)
+
+	asrt.True(matcher(context.WithValue(context.Background(), key, 1)))
+	asrt.True(matcher(context.WithValue(context.Background(), key, int64(3))))
+	asrt.False(matcher(context.WithValue(context.Background(), key, 4)))
+	asrt.False(matcher(context.Background()))
+}
+
+func TestHeaderMatcher(t *testing.T) {
+	t.Parallel()
+	asrt := assert.New(t)
+	key := ContextKey("headers")
+
+	header := http.Header{}
+	header.Add("X-Priority", "low")
+	header.Add("X-Priority", "batch")
+	ctx := context.WithValue(context.Background(), key, header)
+
+	asrt.True(HeaderMatcher(key, "X-Priority")(ctx))
+	asrt.True(HeaderMatcher(key, "X-Priority", "batch")(ctx))
+	asrt.False(HeaderMatcher(key, "X-Priority", "high")(ctx))
+	asrt.False(HeaderMatcher(key, "X-Missing")(ctx))
+}
+
+func TestCombinatorsShortCircuit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("AllOf stops at first false", func(t2 *testing.T) {
+		asrt := assert.New(t2)
+		var calls []int
+		never := func(ctx context.Context) bool {
+			calls = append(calls, 2)
+			return true
+		}
+		matcher := AllOf(
+			func(ctx context.Context) bool { calls = append(calls, 1); return false },
+			never,
+		)
+		asrt.False(matcher(context.Background()))
+		asrt.Equal([]int{1}, calls)
+	})
+
+	t.Run("AnyOf stops at first true", func(t2 *testing.T) {
+		asrt := assert.New(t2)
+		var calls []int
+		never := func(ctx context.Context) bool {
+			calls = append(calls, 2)
+			return false
+		}
+		matcher := AnyOf(
+			func(ctx context.Context) bool { calls = append(calls, 1); return true },
+			never,
+		)
+		asrt.True(matcher(context.Background()))
+		asrt.Equal([]int{1}, calls)
+	})
+
+	t.Run("Not negates", func(t2 *testing.T) {
+		asrt := assert.New(t2)
+		asrt.False(Not(func(ctx context.Context) bool { return true })(context.Background()))
+		asrt.True(Not(func(ctx context.Context) bool { return false })(context.Background()))
+	})
+}
+
+func TestMatcherBuilder(t *testing.T) {
+	t.Parallel()
+	asrt := assert.New(t)
+	b := NewMatcherBuilder()
+
+	matcher, err := b.Build(MatcherConfig{
+		Type: "allOf",
+		Matchers: []MatcherConfig{
+			{Type: "string", Value: "batch", CaseInsensitive: true},
+			{Type: "numericRange", Key: "tenantTier", Min: 1, Max: 2},
+		},
+	})
+	asrt.NoError(err)
+
+	ctx := context.WithValue(context.Background(), StringPredicateContextKey, "Batch")
+	ctx = context.WithValue(ctx, ContextKey("tenantTier"), 2)
+	asrt.True(matcher(ctx))
+
+	ctx = context.WithValue(ctx, ContextKey("tenantTier"), 5)
+	asrt.False(matcher(ctx))
+
+	_, err = b.Build(MatcherConfig{Type: "regexp", Value: "("})
+	asrt.Error(err)
+
+	_, err = b.Build(MatcherConfig{Type: "bogus"})
+	asrt.Error(err)
+}
@@ -0,0 +1,30 @@
+package matchers
+
+import "context"
+
+// NumericRangeMatcher implements a matcher that extracts an integer value
+// from ctx under key and reports whether it falls within [min, max]
+// inclusive. The value may be stored as int, int64, or float64; a float64 is
+// truncated toward zero before the comparison, so 3.5 matches [1, 3].
+func NumericRangeMatcher(key ContextKey, min, max int64) func(ctx context.Context) bool {
+	return func(ctx context.Context) bool {
+		val := ctx.Value(key)
+		if val == nil {
+			return false
+		}
+
+		var num int64
+		switch v := val.(type) {
+		case int:
+			num = int64(v)
+		case int64:
+			num = v
+		case float64:
+			num = int64(v)
+		default:
+			return false
+		}
+
+		return num >= min && num <= max
+	}
+}
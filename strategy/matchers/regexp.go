@@ -0,0 +1,22 @@
+package matchers
+
+import (
+	"context"
+	"regexp"
+)
+
+// RegexpMatcher implements a matcher that extracts a string value from ctx
+// under key and reports whether it matches re.
+func RegexpMatcher(key ContextKey, re *regexp.Regexp) func(ctx context.Context) bool {
+	return func(ctx context.Context) bool {
+		val := ctx.Value(key)
+		if val == nil {
+			return false
+		}
+		strVal, ok := val.(string)
+		if !ok {
+			return false
+		}
+		return re.MatchString(strVal)
+	}
+}
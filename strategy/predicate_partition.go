@@ -0,0 +1,435 @@
+// Package strategy provides Strategy implementations that decide, given a
+// fixed limit, whether a given request may proceed.
+package strategy
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/systay/go-concurrency-limits/core"
+)
+
+// PredicatePartition is a single named partition (bin) of a
+// PredicatePartitionStrategy. A request is routed to the first partition in
+// the strategy's list whose predicate returns true for the request's
+// context.Context, and is guaranteed at least the share of the strategy's
+// total limit that the strategy's RebalanceStrategy last allocated to it (by
+// default, its configured percentage).
+type PredicatePartition struct {
+	name      string
+	percent   float64
+	predicate func(ctx context.Context) bool
+	registry  core.MetricRegistry
+
+	limit int
+	busy  int
+	// target is this partition's most recently rebalanced guaranteed share,
+	// before the busy floor in rebalanceLocked may have inflated limit above
+	// it. busy > target means the partition is currently running on
+	// borrowed, cross-bin excess capacity.
+	target int
+
+	// maxWaiters bounds the FIFO waiter queue used by
+	// PredicatePartitionStrategy.Acquire. 0 means unbounded.
+	maxWaiters int
+	// cond guards the waiter queue; it shares its Locker with the owning
+	// strategy's mutex, set once the partition is attached to a strategy.
+	cond *sync.Cond
+	// queue holds one *waiterEntry per caller currently parked in Acquire,
+	// in FIFO arrival order. Only the caller whose entry is at the front
+	// may attempt admission; an entry is removed from queue the instant
+	// its caller stops waiting, whether by admission or by ctx
+	// cancellation, so a cancelled waiter never blocks the ones behind it.
+	queue   *list.List
+	waiting int
+
+	waiterGauge  core.SampleListener
+	waitLatency  core.SampleListener
+	waitTimeouts core.SampleListener
+
+	// priority is this partition's tier for preemption purposes. Higher
+	// values are higher priority and may preempt a lower-priority
+	// partition's token; partitions at equal priority, including the
+	// default, 0, never preempt each other.
+	priority int
+	// tokens holds every token currently acquired by this partition, in
+	// acquisition order, so a preemption policy can find the most recently
+	// acquired one.
+	tokens []*predicatePartitionToken
+}
+
+// PartitionOption configures optional behavior of a PredicatePartition.
+type PartitionOption func(*PredicatePartition)
+
+// WithMaxWaiters bounds the number of callers that may be parked in this
+// partition's FIFO waiter queue by PredicatePartitionStrategy.Acquire. The
+// default, 0, means unbounded.
+func WithMaxWaiters(n int) PartitionOption {
+	return func(p *PredicatePartition) {
+		p.maxWaiters = n
+	}
+}
+
+// WithPriority sets this partition's preemption priority tier. Higher
+// values are higher priority. The default is 0.
+func WithPriority(priority int) PartitionOption {
+	return func(p *PredicatePartition) {
+		p.priority = priority
+	}
+}
+
+// NewPredicatePartitionWithMetricRegistry creates a new PredicatePartition.
+func NewPredicatePartitionWithMetricRegistry(
+	name string,
+	percent float64,
+	predicate func(ctx context.Context) bool,
+	registry core.MetricRegistry,
+	opts ...PartitionOption,
+) *PredicatePartition {
+	p := &PredicatePartition{
+		name:      name,
+		percent:   percent,
+		predicate: predicate,
+		registry:  registry,
+		limit:     1,
+		queue:     list.New(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.waiterGauge = registry.RegisterGauge("partition.waiters", "partition", name)
+	p.waitLatency = registry.RegisterDistribution("partition.wait_latency_seconds", "partition", name)
+	p.waitTimeouts = registry.RegisterCounter("partition.wait_timeouts", "partition", name)
+	return p
+}
+
+// Name returns the partition's name.
+func (p *PredicatePartition) Name() string {
+	return p.name
+}
+
+// Percent returns the partition's configured share of the total limit.
+func (p *PredicatePartition) Percent() float64 {
+	return p.percent
+}
+
+// Priority returns the partition's preemption priority tier.
+func (p *PredicatePartition) Priority() int {
+	return p.priority
+}
+
+func (p *PredicatePartition) String() string {
+	return fmt.Sprintf("PredicatePartition{name=%s, percent=%f, limit=%d, busy=%d}", p.name, p.percent, p.limit, p.busy)
+}
+
+// partitionPercentTolerance allows for the usual floating point slop when
+// validating that partition percentages sum to 1.0.
+const partitionPercentTolerance = 0.001
+
+// PredicatePartitionStrategy partitions a single limit across multiple named
+// bins selected by predicate. Each bin is guaranteed the share of the total
+// limit allocated to it by the configured RebalanceStrategy (by default, its
+// configured percentage) and may temporarily borrow unused capacity
+// from the other bins, up to the strategy's total limit.
+type PredicatePartitionStrategy struct {
+	mu              sync.Mutex
+	partitions      []*PredicatePartition
+	limit           int
+	busy            int
+	registry        core.MetricRegistry
+	rebalancer      RebalanceStrategy
+	preemptPolicy   PreemptPolicy
+	preemptCallback func(core.Listener)
+}
+
+// Option configures optional behavior of a PredicatePartitionStrategy.
+type Option func(*PredicatePartitionStrategy)
+
+// WithRebalanceStrategy overrides how the total limit is redistributed
+// across partitions, both on construction and on every SetLimit call. The
+// default is StaticPercentRebalancer, which preserves the strategy's
+// original fixed-percentage behavior.
+func WithRebalanceStrategy(rebalancer RebalanceStrategy) Option {
+	return func(s *PredicatePartitionStrategy) {
+		s.rebalancer = rebalancer
+	}
+}
+
+// NewPredicatePartitionStrategyWithMetricRegistry creates a new
+// PredicatePartitionStrategy. The percentages of all partitions must sum to
+// 1.0, within a small tolerance, or an error is returned.
+func NewPredicatePartitionStrategyWithMetricRegistry(
+	partitions []*PredicatePartition,
+	limit int,
+	registry core.MetricRegistry,
+	opts ...Option,
+) (*PredicatePartitionStrategy, error) {
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("partitions must not be empty")
+	}
+
+	total := 0.0
+	for _, p := range partitions {
+		total += p.percent
+	}
+	if total < 1.0-partitionPercentTolerance || total > 1.0+partitionPercentTolerance {
+		return nil, fmt.Errorf("partition percentages must sum to 1.0, got %f", total)
+	}
+
+	s := &PredicatePartitionStrategy{
+		partitions: partitions,
+		registry:   registry,
+	}
+	for _, p := range partitions {
+		p.cond = sync.NewCond(&s.mu)
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.rebalancer == nil {
+		s.rebalancer = StaticPercentRebalancer{}
+	}
+	s.SetLimit(limit)
+	return s, nil
+}
+
+func (s *PredicatePartitionStrategy) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("PredicatePartitionStrategy{partitions=%v, limit=%d, busy=%d}", s.partitions, s.limit, s.busy)
+}
+
+// Limit returns the strategy's current total limit.
+func (s *PredicatePartitionStrategy) Limit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limit
+}
+
+// BusyCount returns the number of tokens currently in use across all
+// partitions.
+func (s *PredicatePartitionStrategy) BusyCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.busy
+}
+
+// BinLimit returns the current limit allocated to the given partition index.
+func (s *PredicatePartitionStrategy) BinLimit(bin int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bin < 0 || bin >= len(s.partitions) {
+		return 0, fmt.Errorf("invalid bin index %d", bin)
+	}
+	return s.partitions[bin].limit, nil
+}
+
+// BinBusyCount returns the number of tokens currently in use by the given
+// partition index.
+func (s *PredicatePartitionStrategy) BinBusyCount(bin int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bin < 0 || bin >= len(s.partitions) {
+		return 0, fmt.Errorf("invalid bin index %d", bin)
+	}
+	return s.partitions[bin].busy, nil
+}
+
+// SetLimit updates the strategy's total limit and asks the configured
+// RebalanceStrategy to reallocate each partition's bin limit. Values below 1
+// are clamped to 1. A bin's limit is never set below its current busy count;
+// any capacity a rebalance would otherwise have reclaimed is parked as
+// "borrowed" and only returned once enough tokens are released.
+func (s *PredicatePartitionStrategy) SetLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit < 1 {
+		limit = 1
+	}
+	s.limit = limit
+	s.rebalanceLocked()
+}
+
+// Rebalance re-runs the configured RebalanceStrategy against the current
+// total limit without changing it. Callers that use a demand-aware
+// rebalancer such as WeightedDemandRebalancer should invoke this
+// periodically (e.g. from a time.Ticker) to shift capacity as demand
+// changes.
+func (s *PredicatePartitionStrategy) Rebalance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rebalanceLocked()
+}
+
+func (s *PredicatePartitionStrategy) rebalanceLocked() {
+	proposed := s.rebalancer.Rebalance(s.partitions, s.limit)
+	for i, p := range s.partitions {
+		target := 1
+		if i < len(proposed) {
+			target = proposed[i]
+		}
+		if target < 1 {
+			target = 1
+		}
+		p.target = target
+
+		binLimit := target
+		if binLimit < p.busy {
+			// Park the excess as borrowed: the bin keeps its current busy
+			// count as its effective limit until releases bring it back
+			// down to the rebalanced target.
+			binLimit = p.busy
+		}
+		p.limit = binLimit
+	}
+}
+
+// findPartitionLocked returns the index of the first partition whose
+// predicate matches ctx, or -1 if none match.
+func (s *PredicatePartitionStrategy) findPartitionLocked(ctx context.Context) int {
+	for i, p := range s.partitions {
+		if p.predicate != nil && p.predicate(ctx) {
+			return i
+		}
+	}
+	return -1
+}
+
+// TryAcquire attempts to acquire a token for the partition matching ctx. It
+// admits the request if the matching partition is within its guaranteed
+// share, or if the strategy as a whole has unused capacity to lend. If that
+// fails and a PreemptPolicy is configured, it will try to preempt a lower
+// priority partition's token using ctx's partition's own Priority. It
+// returns false immediately, without blocking, if no admission is possible.
+func (s *PredicatePartitionStrategy) TryAcquire(ctx context.Context) (core.Listener, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tryAcquireLocked(ctx, nil)
+}
+
+// tryAdmitLocked admits the request against the given bin if it is within
+// its guaranteed share, or if the strategy as a whole has unused capacity to
+// lend. Must be called with s.mu held.
+func (s *PredicatePartitionStrategy) tryAdmitLocked(bin int) (core.Listener, bool) {
+	p := s.partitions[bin]
+	if p.busy < p.limit {
+		p.busy++
+		s.busy++
+		s.observeDemandLocked(bin, false)
+		return s.newTokenLocked(bin, false), true
+	}
+	if s.busy < s.limit {
+		p.busy++
+		s.busy++
+		s.observeDemandLocked(bin, false)
+		return s.newTokenLocked(bin, true), true
+	}
+	return nil, false
+}
+
+// observeDemandLocked reports an admission outcome to the configured
+// rebalancer, if it tracks demand over time.
+func (s *PredicatePartitionStrategy) observeDemandLocked(bin int, rejected bool) {
+	if observer, ok := s.rebalancer.(demandObserver); ok {
+		p := s.partitions[bin]
+		observer.observe(bin, p.busy, p.limit, rejected)
+	}
+}
+
+func (s *PredicatePartitionStrategy) newTokenLocked(bin int, excess bool) core.Listener {
+	token := &predicatePartitionToken{strategy: s, bin: bin, excess: excess}
+	p := s.partitions[bin]
+	p.tokens = append(p.tokens, token)
+	return token
+}
+
+// removeTokenLocked drops token from its partition's in-flight token list.
+// Must be called with s.mu held.
+func (s *PredicatePartitionStrategy) removeTokenLocked(bin int, token *predicatePartitionToken) {
+	p := s.partitions[bin]
+	for i, tok := range p.tokens {
+		if tok == token {
+			p.tokens = append(p.tokens[:i], p.tokens[i+1:]...)
+			return
+		}
+	}
+}
+
+// releaseLocked returns a bin's token, decrementing both the bin's and the
+// strategy's busy counts, then wakes every partition's waiter queue: a
+// release always frees up global capacity, so a waiter parked on a
+// different bin may now be admissible via excess-capacity borrowing even
+// though its own bin's busy count didn't change. Must be called with s.mu
+// held.
+func (s *PredicatePartitionStrategy) releaseLocked(bin int, token *predicatePartitionToken) {
+	if bin < 0 || bin >= len(s.partitions) {
+		return
+	}
+	s.removeTokenLocked(bin, token)
+	p := s.partitions[bin]
+	if p.busy > 0 {
+		p.busy--
+	}
+	// Hand back capacity parked as borrowed by rebalanceLocked: an inflated
+	// limit shrinks with busy, but never below the rebalanced target.
+	if p.limit > p.target {
+		reclaimed := p.busy
+		if reclaimed < p.target {
+			reclaimed = p.target
+		}
+		if reclaimed < p.limit {
+			p.limit = reclaimed
+		}
+	}
+	if s.busy > 0 {
+		s.busy--
+	}
+	for _, pp := range s.partitions {
+		if pp.cond != nil {
+			pp.cond.Broadcast()
+		}
+	}
+}
+
+// BinWaiterCount returns the number of callers currently parked in the given
+// partition's FIFO waiter queue via Acquire.
+func (s *PredicatePartitionStrategy) BinWaiterCount(bin int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bin < 0 || bin >= len(s.partitions) {
+		return 0, fmt.Errorf("invalid bin index %d", bin)
+	}
+	return s.partitions[bin].waiting, nil
+}
+
+// predicatePartitionToken is the core.Listener returned by TryAcquire (and
+// Acquire). Release is idempotent: only the first call returns the token's
+// capacity.
+type predicatePartitionToken struct {
+	strategy *PredicatePartitionStrategy
+	bin      int
+	excess   bool
+	released int32
+}
+
+func (t *predicatePartitionToken) OnSuccess() {}
+
+func (t *predicatePartitionToken) OnIgnore() {}
+
+func (t *predicatePartitionToken) OnDropped() {}
+
+func (t *predicatePartitionToken) IsAcquired() bool {
+	return true
+}
+
+func (t *predicatePartitionToken) Release() {
+	if !atomic.CompareAndSwapInt32(&t.released, 0, 1) {
+		return
+	}
+	t.strategy.mu.Lock()
+	defer t.strategy.mu.Unlock()
+	t.strategy.releaseLocked(t.bin, t)
+}
@@ -0,0 +1,190 @@
+package strategy
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/systay/go-concurrency-limits/core"
+)
+
+// PreemptPolicy controls whether, and how, a PredicatePartitionStrategy may
+// cancel an already-acquired token from a lower-priority partition to admit
+// a higher-priority request once the strategy is otherwise full.
+type PreemptPolicy int
+
+const (
+	// PreemptNone never preempts; a full strategy simply rejects, the
+	// strategy's original behavior.
+	PreemptNone PreemptPolicy = iota
+	// PreemptLowerPriority cancels the most recently acquired token from
+	// the lowest-priority partition that is currently running above its
+	// guaranteed share, as long as that partition's priority is lower than
+	// the requester's.
+	PreemptLowerPriority
+	// PreemptOnlyExcess is a more conservative variant of
+	// PreemptLowerPriority: it only ever cancels tokens that were admitted
+	// via cross-bin excess-capacity borrowing, never a token within a
+	// partition's own reserved share.
+	PreemptOnlyExcess
+)
+
+// WithPreemption enables preemption across partitions using the given
+// policy. The default, PreemptNone, matches the strategy's original
+// behavior.
+func WithPreemption(policy PreemptPolicy) Option {
+	return func(s *PredicatePartitionStrategy) {
+		s.preemptPolicy = policy
+	}
+}
+
+// WithPreemptionCallback registers a callback invoked, with the victim's
+// token, whenever preemption cancels it. Callers typically use this to
+// cancel the context.Context of the work that token was guarding. The
+// callback runs on its own goroutine, outside the strategy's lock.
+func WithPreemptionCallback(callback func(core.Listener)) Option {
+	return func(s *PredicatePartitionStrategy) {
+		s.preemptCallback = callback
+	}
+}
+
+// TryAcquireWithPriority behaves like TryAcquire, but uses priorityOverride,
+// rather than the matching partition's own configured Priority, when
+// deciding whether this request may preempt a lower-priority partition's
+// token.
+func (s *PredicatePartitionStrategy) TryAcquireWithPriority(ctx context.Context, priorityOverride int) (core.Listener, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tryAcquireLocked(ctx, &priorityOverride)
+}
+
+func (s *PredicatePartitionStrategy) tryAcquireLocked(ctx context.Context, priorityOverride *int) (core.Listener, bool) {
+	bin := s.findPartitionLocked(ctx)
+	if bin < 0 {
+		return nil, false
+	}
+
+	// With a preempt policy configured, a bin within its own guaranteed
+	// share must not be admitted via tryAdmitLocked once the strategy is
+	// globally full: some other bin is then running on excess capacity
+	// it borrowed, possibly from this one, and admitting
+	// here without reclaiming that capacity first would push the
+	// strategy's total busy count above its configured limit. Go straight
+	// to preemption instead, so a victim is evicted before this bin's
+	// share is ever handed out.
+	if s.preemptPolicy == PreemptNone || s.busy < s.limit {
+		if token, ok := s.tryAdmitLocked(bin); ok {
+			return token, true
+		}
+	}
+
+	if s.preemptPolicy != PreemptNone {
+		priority := s.partitions[bin].priority
+		if priorityOverride != nil {
+			priority = *priorityOverride
+		}
+		if s.preemptLocked(bin, priority) {
+			if token, ok := s.tryAdmitLocked(bin); ok {
+				return token, true
+			}
+		}
+	}
+
+	s.observeDemandLocked(bin, true)
+	return nil, false
+}
+
+// preemptLocked tries to find and cancel a single victim token, under the
+// strategy's configured PreemptPolicy, to make room for a request from
+// requestingBin at requesterPriority. It reports whether a victim was
+// evicted.
+func (s *PredicatePartitionStrategy) preemptLocked(requestingBin int, requesterPriority int) bool {
+	var victimBin int
+	var victim *predicatePartitionToken
+
+	switch s.preemptPolicy {
+	case PreemptLowerPriority:
+		victimBin, victim = s.findLowerPriorityVictimLocked(requestingBin, requesterPriority)
+	case PreemptOnlyExcess:
+		victimBin, victim = s.findExcessVictimLocked(requestingBin, requesterPriority)
+	default:
+		return false
+	}
+
+	if victim == nil {
+		return false
+	}
+	return s.evictLocked(victimBin, victim)
+}
+
+// findLowerPriorityVictimLocked returns the most recently acquired token
+// from the lowest-priority partition, other than requestingBin, that has a
+// lower priority than requesterPriority and is currently running above its
+// guaranteed share (busy > target).
+func (s *PredicatePartitionStrategy) findLowerPriorityVictimLocked(requestingBin int, requesterPriority int) (int, *predicatePartitionToken) {
+	victimBin := -1
+	victimPriority := 0
+	for i, p := range s.partitions {
+		if i == requestingBin || len(p.tokens) == 0 {
+			continue
+		}
+		if p.busy <= p.target || p.priority >= requesterPriority {
+			continue
+		}
+		if victimBin == -1 || p.priority < victimPriority {
+			victimBin = i
+			victimPriority = p.priority
+		}
+	}
+	if victimBin == -1 {
+		return -1, nil
+	}
+	tokens := s.partitions[victimBin].tokens
+	return victimBin, tokens[len(tokens)-1]
+}
+
+// findExcessVictimLocked returns the most recently acquired
+// excess-capacity-borrowed token from the lowest-priority partition, other
+// than requestingBin, that has a lower priority than requesterPriority.
+// Tokens within a partition's own reserved share are never considered: a
+// token is only eligible if it was admitted as excess *and* the partition
+// is still currently running above its guaranteed share, since a rebalance
+// since acquisition may have grown that share enough to cover it.
+func (s *PredicatePartitionStrategy) findExcessVictimLocked(requestingBin int, requesterPriority int) (int, *predicatePartitionToken) {
+	victimBin := -1
+	victimPriority := 0
+	var victim *predicatePartitionToken
+	for i, p := range s.partitions {
+		if i == requestingBin || p.priority >= requesterPriority || p.busy <= p.target {
+			continue
+		}
+		for j := len(p.tokens) - 1; j >= 0; j-- {
+			if p.tokens[j].excess {
+				if victimBin == -1 || p.priority < victimPriority {
+					victimBin = i
+					victimPriority = p.priority
+					victim = p.tokens[j]
+				}
+				break
+			}
+		}
+	}
+	return victimBin, victim
+}
+
+// evictLocked cancels victim, a token belonging to the partition at bin. It
+// races the token's own Release and only proceeds if it wins, so a victim
+// can never be evicted twice; on a win it frees the token's capacity the
+// same way Release does. Must be called with s.mu held.
+func (s *PredicatePartitionStrategy) evictLocked(bin int, victim *predicatePartitionToken) bool {
+	if !atomic.CompareAndSwapInt32(&victim.released, 0, 1) {
+		return false
+	}
+
+	s.releaseLocked(bin, victim)
+
+	if s.preemptCallback != nil {
+		callback := s.preemptCallback
+		go callback(victim)
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systay/go-concurrency-limits/core"
+	"github.com/systay/go-concurrency-limits/strategy/matchers"
+)
+
+func makePriorityPartitions() []*PredicatePartition {
+	batchPartition := NewPredicatePartitionWithMetricRegistry(
+		"batch", 0.3, matchers.StringPredicateMatcher("batch", false), core.EmptyMetricRegistryInstance)
+	livePartition := NewPredicatePartitionWithMetricRegistry(
+		"live", 0.7, matchers.StringPredicateMatcher("live", false), core.EmptyMetricRegistryInstance, WithPriority(10))
+	return []*PredicatePartition{batchPartition, livePartition}
+}
+
+func TestPreemption(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PreemptNoneNeverEvicts", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(makePriorityPartitions(), 10, core.EmptyMetricRegistryInstance)
+		asrt.NoError(err)
+
+		// exhaust both partitions' own reserved shares, and therefore the
+		// whole strategy, so further requests have nothing left to borrow.
+		ctxBatch := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "batch")
+		for i := 0; i < 3; i++ {
+			_, ok := s.TryAcquire(ctxBatch)
+			asrt.True(ok)
+		}
+		ctxLive := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "live")
+		for i := 0; i < 7; i++ {
+			_, ok := s.TryAcquire(ctxLive)
+			asrt.True(ok)
+		}
+
+		token, ok := s.TryAcquireWithPriority(ctxLive, 10)
+		asrt.False(ok)
+		asrt.Nil(token)
+	})
+
+	t.Run("PreemptLowerPriorityEvictsLowestPriorityOverShare", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		evicted := make(chan core.Listener, 1)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(
+			makePriorityPartitions(),
+			10,
+			core.EmptyMetricRegistryInstance,
+			WithPreemption(PreemptLowerPriority),
+			WithPreemptionCallback(func(l core.Listener) { evicted <- l }))
+		asrt.NoError(err)
+
+		ctxBatch := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "batch")
+		var lastBatchToken core.Listener
+		for i := 0; i < 10; i++ {
+			token, ok := s.TryAcquire(ctxBatch)
+			asrt.True(ok)
+			lastBatchToken = token
+		}
+		asrt.Equal(10, s.BusyCount())
+
+		ctxLive := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "live")
+		token, ok := s.TryAcquireWithPriority(ctxLive, 10)
+		asrt.True(ok)
+		asrt.NotNil(token)
+
+		select {
+		case victim := <-evicted:
+			asrt.Same(lastBatchToken, victim)
+		case <-time.After(time.Second):
+			t2.Fatal("expected preemption callback to fire")
+		}
+
+		asrt.Equal(10, s.BusyCount())
+		batchBusy, err := s.BinBusyCount(0)
+		asrt.NoError(err)
+		asrt.Equal(9, batchBusy)
+
+		// the victim's own Release, arriving after preemption, must be a
+		// no-op rather than double-freeing capacity.
+		lastBatchToken.Release()
+		asrt.Equal(10, s.BusyCount())
+	})
+
+	t.Run("PreemptOnlyExcessNeverTouchesReservedShare", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(
+			makePriorityPartitions(),
+			10,
+			core.EmptyMetricRegistryInstance,
+			WithPreemption(PreemptOnlyExcess))
+		asrt.NoError(err)
+
+		// fill batch to exactly its reserved share (3); none of these
+		// tokens are excess-borrowed.
+		ctxBatch := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "batch")
+		for i := 0; i < 3; i++ {
+			_, ok := s.TryAcquire(ctxBatch)
+			asrt.True(ok)
+		}
+		// fill live to exactly its reserved share (7) too, so the strategy
+		// is globally full with no excess tokens anywhere.
+		ctxLive := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "live")
+		for i := 0; i < 7; i++ {
+			_, ok := s.TryAcquire(ctxLive)
+			asrt.True(ok)
+		}
+
+		// a further live request has nothing to preempt: batch's tokens are
+		// all within its reserved share.
+		token, ok := s.TryAcquireWithPriority(ctxLive, 10)
+		asrt.False(ok)
+		asrt.Nil(token)
+	})
+}
@@ -0,0 +1,223 @@
+package strategy
+
+import "sync"
+
+// RebalanceStrategy computes the desired per-partition limit for a
+// PredicatePartitionStrategy's total limit. It is consulted whenever the
+// total limit changes (SetLimit) and, for implementations that want to
+// react to shifting demand without a limit change, whenever the strategy's
+// Rebalance method is invoked on a timer.
+//
+// A RebalanceStrategy is free to propose a limit below a partition's current
+// busy count; the strategy never actually shrinks a bin below its busy
+// count, instead parking the difference as borrowed capacity until enough
+// tokens are released.
+type RebalanceStrategy interface {
+	// Rebalance returns the desired limit for each partition, in the same
+	// order as partitions. The returned slice should have one entry per
+	// partition.
+	Rebalance(partitions []*PredicatePartition, total int) []int
+}
+
+// demandObserver is implemented by RebalanceStrategy implementations that
+// want to be informed of admission outcomes so they can track demand over
+// time.
+type demandObserver interface {
+	observe(bin int, busy, limit int, rejected bool)
+}
+
+// StaticPercentRebalancer allocates each partition a fixed share of the
+// total limit proportional to its configured percent. This is the default
+// rebalance strategy and preserves PredicatePartitionStrategy's original
+// behavior.
+type StaticPercentRebalancer struct{}
+
+// Rebalance implements RebalanceStrategy.
+func (StaticPercentRebalancer) Rebalance(partitions []*PredicatePartition, total int) []int {
+	limits := make([]int, len(partitions))
+	for i, p := range partitions {
+		limit := int(p.percent * float64(total))
+		if limit < 1 {
+			limit = 1
+		}
+		limits[i] = limit
+	}
+	return limits
+}
+
+// StickyRebalancer preserves each partition's previous allocation across
+// calls, reallocating only the delta introduced by a change in the total
+// limit, split across partitions proportional to their configured percent.
+// The zero value is ready to use; the first Rebalance call for a given
+// partition count falls back to StaticPercentRebalancer.
+type StickyRebalancer struct {
+	mu       sync.Mutex
+	previous []int
+}
+
+// Rebalance implements RebalanceStrategy.
+func (r *StickyRebalancer) Rebalance(partitions []*PredicatePartition, total int) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.previous) != len(partitions) {
+		r.previous = StaticPercentRebalancer{}.Rebalance(partitions, total)
+		return append([]int(nil), r.previous...)
+	}
+
+	prevTotal := 0
+	for _, l := range r.previous {
+		prevTotal += l
+	}
+	delta := total - prevTotal
+
+	limits := append([]int(nil), r.previous...)
+	if delta != 0 && len(partitions) > 0 {
+		remaining := delta
+		for i, p := range partitions {
+			if i == len(partitions)-1 {
+				limits[i] += remaining
+				break
+			}
+			share := int(float64(delta) * p.percent)
+			limits[i] += share
+			remaining -= share
+		}
+	}
+	for i, l := range limits {
+		if l < 1 {
+			limits[i] = 1
+		}
+	}
+
+	r.previous = append([]int(nil), limits...)
+	return limits
+}
+
+// WeightedDemandRebalancer shifts a configurable fraction of unused capacity
+// from low-utilization partitions to partitions under pressure, based on an
+// exponentially weighted moving average of each partition's observed
+// rejection/busy demand. It starts from a StaticPercentRebalancer allocation
+// and adjusts it each time Rebalance is called.
+type WeightedDemandRebalancer struct {
+	// ShiftFraction is the fraction, in (0, 1], of a donor partition's
+	// unused capacity moved to starved partitions on each rebalance.
+	ShiftFraction float64
+	// Alpha is the EWMA smoothing factor, in (0, 1], applied to each new
+	// demand sample. Higher values react faster to recent traffic.
+	Alpha float64
+
+	mu     sync.Mutex
+	demand []float64
+}
+
+// NewWeightedDemandRebalancer creates a WeightedDemandRebalancer with the
+// given shift fraction and EWMA smoothing factor.
+func NewWeightedDemandRebalancer(shiftFraction, alpha float64) *WeightedDemandRebalancer {
+	return &WeightedDemandRebalancer{ShiftFraction: shiftFraction, Alpha: alpha}
+}
+
+func (r *WeightedDemandRebalancer) ensureSizeLocked(n int) {
+	for len(r.demand) < n {
+		r.demand = append(r.demand, 0)
+	}
+}
+
+// observe implements demandObserver, folding an admission outcome into the
+// partition's EWMA demand. A rejection counts as full demand (1.0); an
+// admission counts as the bin's resulting utilization (busy/limit).
+func (r *WeightedDemandRebalancer) observe(bin int, busy, limit int, rejected bool) {
+	sample := 1.0
+	if !rejected && limit > 0 {
+		sample = float64(busy) / float64(limit)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if bin < 0 {
+		return
+	}
+	r.ensureSizeLocked(bin + 1)
+	alpha := r.Alpha
+	if alpha <= 0 {
+		alpha = 1
+	}
+	r.demand[bin] = alpha*sample + (1-alpha)*r.demand[bin]
+}
+
+// Rebalance implements RebalanceStrategy.
+func (r *WeightedDemandRebalancer) Rebalance(partitions []*PredicatePartition, total int) []int {
+	base := StaticPercentRebalancer{}.Rebalance(partitions, total)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ensureSizeLocked(len(partitions))
+
+	shiftFraction := r.ShiftFraction
+	if shiftFraction <= 0 || len(partitions) == 0 {
+		return base
+	}
+
+	avgDemand := 0.0
+	for _, d := range r.demand[:len(partitions)] {
+		avgDemand += d
+	}
+	avgDemand /= float64(len(partitions))
+
+	limits := append([]int(nil), base...)
+
+	// Donors: partitions demanding less than average give up a fraction of
+	// their currently unused allocation into a shared pool.
+	pool := 0
+	for i, p := range partitions {
+		if r.demand[i] >= avgDemand {
+			continue
+		}
+		unused := limits[i] - p.busy
+		if unused <= 0 {
+			continue
+		}
+		give := int(float64(unused) * shiftFraction)
+		if give <= 0 {
+			continue
+		}
+		limits[i] -= give
+		pool += give
+	}
+	if pool == 0 {
+		return limits
+	}
+
+	// Recipients: partitions demanding more than average, weighted by how
+	// far above average they are.
+	weights := make([]float64, len(partitions))
+	totalWeight := 0.0
+	for i := range partitions {
+		if r.demand[i] <= avgDemand {
+			continue
+		}
+		weights[i] = r.demand[i] - avgDemand
+		totalWeight += weights[i]
+	}
+	if totalWeight == 0 {
+		limits[0] += pool
+		return limits
+	}
+	distributed := 0
+	for i := range partitions {
+		if weights[i] == 0 {
+			continue
+		}
+		share := int(float64(pool) * (weights[i] / totalWeight))
+		limits[i] += share
+		distributed += share
+	}
+	limits[len(limits)-1] += pool - distributed
+
+	for i, l := range limits {
+		if l < 1 {
+			limits[i] = 1
+		}
+	}
+	return limits
+}
@@ -0,0 +1,121 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systay/go-concurrency-limits/core"
+	"github.com/systay/go-concurrency-limits/strategy/matchers"
+)
+
+func TestRebalanceStrategies(t *testing.T) {
+	t.Parallel()
+
+	t.Run("StaticPercentRebalancerIsDefault", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(
+			makeTestPartitions(),
+			1,
+			core.EmptyMetricRegistryInstance)
+		asrt.NoError(err)
+		s.SetLimit(10)
+
+		limit, err := s.BinLimit(0)
+		asrt.NoError(err)
+		asrt.Equal(3, limit)
+
+		limit, err = s.BinLimit(1)
+		asrt.NoError(err)
+		asrt.Equal(7, limit)
+	})
+
+	t.Run("StickyRebalancerPreservesAllocationAcrossGrowth", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(
+			makeTestPartitions(),
+			1,
+			core.EmptyMetricRegistryInstance,
+			WithRebalanceStrategy(&StickyRebalancer{}))
+		asrt.NoError(err)
+
+		s.SetLimit(10)
+		batchLimit, err := s.BinLimit(0)
+		asrt.NoError(err)
+		liveLimit, err := s.BinLimit(1)
+		asrt.NoError(err)
+		asrt.Equal(10, batchLimit+liveLimit)
+
+		// growing the total should only redistribute the delta, not
+		// recompute from scratch.
+		s.SetLimit(20)
+		newBatchLimit, err := s.BinLimit(0)
+		asrt.NoError(err)
+		newLiveLimit, err := s.BinLimit(1)
+		asrt.NoError(err)
+		asrt.Equal(20, newBatchLimit+newLiveLimit)
+		asrt.True(newBatchLimit >= batchLimit)
+		asrt.True(newLiveLimit >= liveLimit)
+	})
+
+	t.Run("RebalanceNeverDropsBelowBusy", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(
+			makeTestPartitions(),
+			1,
+			core.EmptyMetricRegistryInstance,
+			WithRebalanceStrategy(&StickyRebalancer{}))
+		asrt.NoError(err)
+		s.SetLimit(10)
+
+		ctxBatch := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "batch")
+		for i := 0; i < 3; i++ {
+			token, ok := s.TryAcquire(ctxBatch)
+			asrt.True(ok)
+			asrt.NotNil(token)
+		}
+
+		// shrinking the total must not drop batch's limit below its busy count.
+		s.SetLimit(2)
+		limit, err := s.BinLimit(0)
+		asrt.NoError(err)
+		asrt.Equal(3, limit)
+	})
+
+	t.Run("WeightedDemandRebalancerShiftsTowardStarvedPartition", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		rebalancer := NewWeightedDemandRebalancer(1.0, 1.0)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(
+			makeTestPartitions(),
+			1,
+			core.EmptyMetricRegistryInstance,
+			WithRebalanceStrategy(rebalancer))
+		asrt.NoError(err)
+		s.SetLimit(10)
+
+		ctxLive := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "live")
+		liveLimit, err := s.BinLimit(1)
+		asrt.NoError(err)
+		for i := 0; i < liveLimit; i++ {
+			token, ok := s.TryAcquire(ctxLive)
+			asrt.True(ok)
+			asrt.NotNil(token)
+		}
+		// live's own share is now exhausted; the next request is admitted
+		// via excess capacity borrowed from the idle batch bin, which
+		// records further demand against live.
+		_, ok := s.TryAcquire(ctxLive)
+		asrt.True(ok)
+
+		s.Rebalance()
+
+		newLiveLimit, err := s.BinLimit(1)
+		asrt.NoError(err)
+		asrt.True(newLiveLimit >= liveLimit, "expected live's limit to grow or hold under sustained demand")
+	})
+}
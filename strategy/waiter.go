@@ -0,0 +1,93 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/systay/go-concurrency-limits/core"
+)
+
+// waiterEntry marks one caller's position in a partition's FIFO waiter
+// queue. It carries no data; its identity (the *list.Element wrapping it) is
+// all Acquire needs to tell whether it is at the front of the queue.
+type waiterEntry struct{}
+
+// Acquire behaves like TryAcquire, but if the partition matching ctx cannot
+// admit the caller immediately, it parks the caller in that partition's
+// bounded FIFO waiter queue instead of rejecting outright. A parked waiter
+// is woken, and re-evaluates admission, whenever any bin releases a token:
+// a release always frees global capacity, so a waiter on the "batch"
+// partition may proceed either because a batch token freed up or because
+// the global busy count dropped and batch is still under its own bin limit.
+//
+// Acquire returns ctx.Err() if ctx is cancelled while waiting, and an error
+// immediately if the partition's waiter queue is already at its configured
+// WithMaxWaiters bound.
+func (s *PredicatePartitionStrategy) Acquire(ctx context.Context) (core.Listener, error) {
+	s.mu.Lock()
+
+	bin := s.findPartitionLocked(ctx)
+	if bin < 0 {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("no partition matches the given context")
+	}
+
+	if token, ok := s.tryAdmitLocked(bin); ok {
+		s.mu.Unlock()
+		return token, nil
+	}
+
+	p := s.partitions[bin]
+	if p.maxWaiters > 0 && p.waiting >= p.maxWaiters {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("partition %q waiter queue is full", p.name)
+	}
+
+	elem := p.queue.PushBack(&waiterEntry{})
+	p.waiting++
+	p.waiterGauge.AddSample(float64(p.waiting))
+	start := time.Now()
+
+	// Wake this waiter's Wait() call if ctx is cancelled, since sync.Cond
+	// has no notion of a context deadline on its own.
+	stopWatch := make(chan struct{})
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.mu.Lock()
+				p.cond.Broadcast()
+				s.mu.Unlock()
+			case <-stopWatch:
+			}
+		}()
+	}
+	defer close(stopWatch)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			p.queue.Remove(elem)
+			p.waiting--
+			p.waiterGauge.AddSample(float64(p.waiting))
+			p.waitTimeouts.AddSample(1)
+			p.cond.Broadcast()
+			s.mu.Unlock()
+			return nil, err
+		}
+
+		if p.queue.Front() == elem {
+			if token, ok := s.tryAdmitLocked(bin); ok {
+				p.queue.Remove(elem)
+				p.waiting--
+				p.waiterGauge.AddSample(float64(p.waiting))
+				p.waitLatency.AddSample(time.Since(start).Seconds())
+				p.cond.Broadcast()
+				s.mu.Unlock()
+				return token, nil
+			}
+		}
+
+		p.cond.Wait()
+	}
+}
@@ -0,0 +1,181 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/systay/go-concurrency-limits/core"
+	"github.com/systay/go-concurrency-limits/strategy/matchers"
+)
+
+func TestPredicatePartitionStrategyAcquire(t *testing.T) {
+	t.Parallel()
+
+	t.Run("AcquireAdmitsImmediatelyWhenCapacityAvailable", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(makeTestPartitions(), 10, core.EmptyMetricRegistryInstance)
+		asrt.NoError(err)
+
+		ctx := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "batch")
+		token, err := s.Acquire(ctx)
+		asrt.NoError(err)
+		asrt.NotNil(token)
+
+		waiters, err := s.BinWaiterCount(0)
+		asrt.NoError(err)
+		asrt.Equal(0, waiters)
+	})
+
+	t.Run("AcquireWakesOnRelease", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(makeTestPartitions(), 1, core.EmptyMetricRegistryInstance)
+		asrt.NoError(err)
+
+		ctx := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "batch")
+		held, ok := s.TryAcquire(ctx)
+		asrt.True(ok)
+
+		result := make(chan error, 1)
+		go func() {
+			token, err := s.Acquire(ctx)
+			if err == nil {
+				token.Release()
+			}
+			result <- err
+		}()
+
+		asrt.Eventually(func() bool {
+			waiters, err := s.BinWaiterCount(0)
+			return err == nil && waiters == 1
+		}, time.Second, time.Millisecond)
+
+		held.Release()
+
+		select {
+		case err := <-result:
+			asrt.NoError(err)
+		case <-time.After(time.Second):
+			t2.Fatal("Acquire never woke up after release")
+		}
+	})
+
+	t.Run("AcquireRespectsContextCancellation", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(makeTestPartitions(), 1, core.EmptyMetricRegistryInstance)
+		asrt.NoError(err)
+
+		ctx := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "batch")
+		_, ok := s.TryAcquire(ctx)
+		asrt.True(ok)
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		result := make(chan error, 1)
+		go func() {
+			_, err := s.Acquire(cancelCtx)
+			result <- err
+		}()
+
+		asrt.Eventually(func() bool {
+			waiters, err := s.BinWaiterCount(0)
+			return err == nil && waiters == 1
+		}, time.Second, time.Millisecond)
+
+		cancel()
+
+		select {
+		case err := <-result:
+			asrt.ErrorIs(err, context.Canceled)
+		case <-time.After(time.Second):
+			t2.Fatal("Acquire never unblocked after cancellation")
+		}
+
+		waiters, err := s.BinWaiterCount(0)
+		asrt.NoError(err)
+		asrt.Equal(0, waiters)
+	})
+
+	t.Run("NonHeadCancellationDoesNotStallHeadWaiter", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(makeTestPartitions(), 1, core.EmptyMetricRegistryInstance)
+		asrt.NoError(err)
+
+		ctx := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "batch")
+		held, ok := s.TryAcquire(ctx)
+		asrt.True(ok)
+
+		firstResult := make(chan error, 1)
+		go func() {
+			token, err := s.Acquire(ctx)
+			if err == nil {
+				token.Release()
+			}
+			firstResult <- err
+		}()
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		secondResult := make(chan error, 1)
+		go func() {
+			_, err := s.Acquire(cancelCtx)
+			secondResult <- err
+		}()
+
+		asrt.Eventually(func() bool {
+			waiters, err := s.BinWaiterCount(0)
+			return err == nil && waiters == 2
+		}, time.Second, time.Millisecond)
+
+		// Cancel the second, non-head waiter before the first one is ever
+		// served. Its queue entry must be removed so the head waiter can
+		// still be admitted.
+		cancel()
+		select {
+		case err := <-secondResult:
+			asrt.ErrorIs(err, context.Canceled)
+		case <-time.After(time.Second):
+			t2.Fatal("non-head waiter never unblocked after cancellation")
+		}
+
+		held.Release()
+
+		select {
+		case err := <-firstResult:
+			asrt.NoError(err)
+		case <-time.After(time.Second):
+			t2.Fatal("head waiter never got admitted after non-head cancellation")
+		}
+	})
+
+	t.Run("WithMaxWaitersRejectsWhenQueueIsFull", func(t2 *testing.T) {
+		t2.Parallel()
+		asrt := assert.New(t2)
+		batchPartition := NewPredicatePartitionWithMetricRegistry(
+			"batch", 0.3, matchers.StringPredicateMatcher("batch", false), core.EmptyMetricRegistryInstance, WithMaxWaiters(1))
+		livePartition := NewPredicatePartitionWithMetricRegistry(
+			"live", 0.7, matchers.StringPredicateMatcher("live", false), core.EmptyMetricRegistryInstance)
+		s, err := NewPredicatePartitionStrategyWithMetricRegistry(
+			[]*PredicatePartition{batchPartition, livePartition}, 1, core.EmptyMetricRegistryInstance)
+		asrt.NoError(err)
+
+		ctx := context.WithValue(context.Background(), matchers.StringPredicateContextKey, "batch")
+		_, ok := s.TryAcquire(ctx)
+		asrt.True(ok)
+
+		go func() {
+			_, _ = s.Acquire(ctx)
+		}()
+		asrt.Eventually(func() bool {
+			waiters, err := s.BinWaiterCount(0)
+			return err == nil && waiters == 1
+		}, time.Second, time.Millisecond)
+
+		_, err = s.Acquire(ctx)
+		asrt.Error(err)
+	})
+}